@@ -0,0 +1,406 @@
+// Copyright ©2011-2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bgzf provides transparent reading and writing of gzip and BGZF
+// (blocked gzip) compressed streams.
+//
+// BGZF concatenates a series of independent gzip members, each holding at
+// most 64kb of uncompressed data, so that any block can be decompressed in
+// isolation. This lets a caller address a position in the uncompressed
+// stream with a virtual offset: the upper 48 bits are the byte offset of the
+// block's first byte in the compressed file, and the lower 16 bits are the
+// byte offset of the position within that block's uncompressed data. Plain
+// gzip streams have no such structure and so only support sequential
+// decompression.
+package bgzf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// bgzfSI1, bgzfSI2 identify the "BC" extra subfield that marks a gzip member
+// as a BGZF block and carries the block's total compressed size.
+const bgzfSI1, bgzfSI2 = 'B', 'C'
+
+// MaxBlockSize is the largest amount of uncompressed data Writer puts in a
+// single BGZF block. It is kept below 64kb so that even incompressible data
+// compresses to a block whose size BSIZE can record in 16 bits.
+const MaxBlockSize = 0xff00
+
+// ErrNotSeekable is returned by Reader.Seek when the underlying reader is
+// not an io.ReadSeeker, or by Offset/Seek when the stream is plain gzip
+// rather than BGZF.
+var ErrNotSeekable = errors.New("bgzf: stream does not support virtual offsets")
+
+// Offset returns the virtual file offset composed of a compressed block
+// offset and a within-block uncompressed offset.
+func Offset(compressed int64, within uint16) uint64 {
+	return uint64(compressed)<<16 | uint64(within)
+}
+
+// SplitOffset splits a virtual offset into the compressed block offset and
+// the within-block uncompressed offset that composed it.
+func SplitOffset(voffset uint64) (compressed int64, within uint16) {
+	return int64(voffset >> 16), uint16(voffset & 0xffff)
+}
+
+// Reader decompresses a gzip or BGZF stream, or passes an uncompressed
+// stream through unchanged. BGZF streams are decompressed one block at a
+// time, each bounded to its own BSIZE-declared extent so that
+// compress/flate's internal read-ahead buffering can never cross into the
+// next block, so that Offset and Seek can address data by virtual offset.
+// Plain gzip streams are decompressed as a single continuous member.
+type Reader struct {
+	src io.Reader
+	rs  io.ReadSeeker // non-nil when src can be sought, enabling Seek.
+
+	passthrough bool // true if src carries no gzip magic header at all.
+	blocked     bool // true if the stream's members carry a BGZF "BC" extra subfield.
+
+	raw        *countingReader
+	gz         *gzip.Reader // continuous decompressor, used only when !blocked.
+	block      []byte
+	within     int
+	blockStart int64 // compressed offset at which the current block began.
+}
+
+// NewReader returns a Reader that decompresses r, detecting a leading gzip
+// magic header and, within that, a BGZF "BC" extra subfield. A stream with
+// neither is read back unchanged.
+//
+// If r is also an io.ReadSeeker, it is sniffed by reading its first two
+// bytes and seeking back to the starting position, so that r itself, not
+// some intermediate buffering, is what Seek later repositions; this keeps
+// Seek available whenever the caller's original source supports it. A
+// non-seekable r is instead wrapped in a small internal buffer, and Seek is
+// unavailable for it, exactly as it would have been before sniffing.
+func NewReader(r io.Reader) (*Reader, error) {
+	rs, seekable := r.(io.ReadSeeker)
+
+	var src io.Reader = r
+	var magic [2]byte
+	var haveMagic bool
+	var base int64 // compressed offset of the stream's first byte in r.
+
+	if seekable {
+		start, err := rs.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		n, err := io.ReadFull(rs, magic[:])
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+		haveMagic = n == len(magic)
+		if _, err := rs.Seek(start, io.SeekStart); err != nil {
+			return nil, err
+		}
+		base = start
+		src = rs
+	} else {
+		br := bufio.NewReader(r)
+		peek, err := br.Peek(len(magic))
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		haveMagic = len(peek) == len(magic)
+		copy(magic[:], peek)
+		src = br
+		rs = nil
+	}
+
+	z := &Reader{src: src, rs: rs, raw: &countingReader{r: src, n: base}}
+
+	if !haveMagic || magic[0] != 0x1f || magic[1] != 0x8b {
+		z.passthrough = true
+		return z, nil
+	}
+
+	start := z.raw.n
+	header, bsize, blocked, err := readMemberHeader(z.raw)
+	if err != nil {
+		if err == io.EOF {
+			return z, nil
+		}
+		return nil, err
+	}
+	z.blocked = blocked
+
+	if !blocked {
+		z.gz, err = gzip.NewReader(io.MultiReader(bytes.NewReader(header), z.raw))
+		if err != nil {
+			return nil, err
+		}
+		return z, nil
+	}
+
+	if err := z.decodeBlock(start, header, bsize); err != nil {
+		return nil, err
+	}
+	return z, nil
+}
+
+// Read decompresses data from the underlying stream into p, reading
+// successive blocks as required.
+func (z *Reader) Read(p []byte) (int, error) {
+	if z.passthrough {
+		return z.raw.Read(p)
+	}
+	if !z.blocked {
+		if z.gz == nil {
+			return 0, io.EOF
+		}
+		return z.gz.Read(p)
+	}
+	if z.within >= len(z.block) {
+		if err := z.readBlock(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, z.block[z.within:])
+	z.within += n
+	return n, nil
+}
+
+// Offset returns the virtual offset of the next byte Read will return. It
+// is only meaningful for BGZF streams; plain gzip streams return an error.
+func (z *Reader) Offset() (uint64, error) {
+	if !z.blocked {
+		return 0, ErrNotSeekable
+	}
+	if z.within > 0xffff {
+		// A full 64kb block has been read; the next byte opens the next block.
+		return Offset(z.raw.n, 0), nil
+	}
+	return Offset(z.blockStart, uint16(z.within)), nil
+}
+
+// Seek moves to the block identified by the compressed offset component of
+// voffset and discards the within-block prefix identified by its
+// uncompressed offset component. It requires a BGZF stream over an
+// io.ReadSeeker.
+func (z *Reader) Seek(voffset uint64) error {
+	if z.rs == nil || !z.blocked {
+		return ErrNotSeekable
+	}
+	compressed, within := SplitOffset(voffset)
+	if _, err := z.rs.Seek(compressed, io.SeekStart); err != nil {
+		return err
+	}
+	z.raw = &countingReader{r: z.src, n: compressed}
+	if err := z.readBlock(); err != nil {
+		return err
+	}
+	if int(within) > len(z.block) {
+		return errors.New("bgzf: within-block offset past end of block")
+	}
+	z.within = int(within)
+	return nil
+}
+
+// readBlock decompresses the next BGZF block from the stream, bounding the
+// decompressor to exactly the block's BSIZE-declared length.
+func (z *Reader) readBlock() error {
+	start := z.raw.n
+	header, bsize, blocked, err := readMemberHeader(z.raw)
+	if err != nil {
+		return err
+	}
+	if !blocked {
+		return errors.New("bgzf: expected a BGZF block but member has no BC extra subfield")
+	}
+	return z.decodeBlock(start, header, bsize)
+}
+
+// decodeBlock reads the remainder of a BGZF member of total size bsize,
+// whose header bytes have already been consumed, into a private buffer and
+// decompresses it there. Confining the deflate reader to a buffer sized from
+// BSIZE, rather than the live stream, prevents its internal read-ahead
+// buffering from crossing into the next block.
+func (z *Reader) decodeBlock(start int64, header []byte, bsize int) error {
+	if bsize < len(header) {
+		return errors.New("bgzf: invalid BGZF block size")
+	}
+	member := make([]byte, bsize)
+	copy(member, header)
+	if _, err := io.ReadFull(z.raw, member[len(header):]); err != nil {
+		return err
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(member))
+	if err != nil {
+		return err
+	}
+	block, err := io.ReadAll(gz)
+	if err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	z.block = block
+	z.within = 0
+	z.blockStart = start
+	return nil
+}
+
+// gzipFixedHeaderLen is the size, in bytes, of the fixed portion of a gzip
+// member header (RFC 1952 §2.3.1), before any optional FEXTRA data.
+const gzipFixedHeaderLen = 10
+
+// flgFExtra is the FLG bit indicating a gzip member header carries an
+// optional extra field.
+const flgFExtra = 0x04
+
+// readMemberHeader reads one gzip member header from r, returning its raw
+// bytes (so the caller can hand them, along with the rest of the member, to
+// compress/gzip) and, if present, the BGZF "BC" extra subfield's BSIZE value
+// (the total size of the member, including this header, in bytes).
+func readMemberHeader(r io.Reader) (header []byte, bsize int, blocked bool, err error) {
+	fixed := make([]byte, gzipFixedHeaderLen)
+	if _, err = io.ReadFull(r, fixed); err != nil {
+		return nil, 0, false, err
+	}
+	if fixed[0] != 0x1f || fixed[1] != 0x8b {
+		return nil, 0, false, errors.New("bgzf: invalid gzip member header")
+	}
+	header = fixed
+
+	if fixed[3]&flgFExtra == 0 {
+		return header, 0, false, nil
+	}
+
+	xlenBytes := make([]byte, 2)
+	if _, err = io.ReadFull(r, xlenBytes); err != nil {
+		return nil, 0, false, err
+	}
+	header = append(header, xlenBytes...)
+
+	xlen := int(binary.LittleEndian.Uint16(xlenBytes))
+	extra := make([]byte, xlen)
+	if xlen > 0 {
+		if _, err = io.ReadFull(r, extra); err != nil {
+			return nil, 0, false, err
+		}
+	}
+	header = append(header, extra...)
+
+	for rest := extra; len(rest) >= 4; {
+		si1, si2, slen := rest[0], rest[1], int(binary.LittleEndian.Uint16(rest[2:4]))
+		if len(rest) < 4+slen {
+			return nil, 0, false, errors.New("bgzf: malformed extra subfield")
+		}
+		if si1 == bgzfSI1 && si2 == bgzfSI2 && slen == 2 {
+			bsize = int(binary.LittleEndian.Uint16(rest[4:6])) + 1
+			blocked = true
+		}
+		rest = rest[4+slen:]
+	}
+
+	return header, bsize, blocked, nil
+}
+
+// countingReader wraps an io.Reader, recording the number of bytes read
+// through it so block boundaries can be measured.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Writer compresses data into a BGZF stream: a series of independent gzip
+// members, each holding at most MaxBlockSize bytes of uncompressed data and
+// carrying a "BC" extra subfield recording its own compressed size.
+type Writer struct {
+	w       io.Writer
+	buf     bytes.Buffer
+	written int64 // compressed bytes flushed so far; the offset of the next block.
+}
+
+// NewWriter returns a Writer that writes a BGZF stream to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write buffers p, flushing completed MaxBlockSize blocks as they fill.
+func (z *Writer) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		room := MaxBlockSize - z.buf.Len()
+		chunk := p
+		if len(chunk) > room {
+			chunk = chunk[:room]
+		}
+		z.buf.Write(chunk)
+		p = p[len(chunk):]
+		if z.buf.Len() >= MaxBlockSize {
+			if err := z.flushBlock(); err != nil {
+				return n - len(p), err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Offset returns the virtual offset of the next byte that will be written:
+// the compressed offset of the block currently being filled, and the
+// within-block uncompressed offset of the buffered data.
+func (z *Writer) Offset() uint64 {
+	return Offset(z.written, uint16(z.buf.Len()))
+}
+
+// Close flushes any buffered data as a final block and closes the
+// underlying writer if it implements io.Closer.
+func (z *Writer) Close() error {
+	if z.buf.Len() > 0 {
+		if err := z.flushBlock(); err != nil {
+			return err
+		}
+	}
+	if c, ok := z.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// flushBlock compresses the buffered data as a single BGZF block and writes
+// it to the underlying writer.
+func (z *Writer) flushBlock() error {
+	var member bytes.Buffer
+	gz, err := gzip.NewWriterLevel(&member, gzip.DefaultCompression)
+	if err != nil {
+		return err
+	}
+	// BSIZE (the last two bytes of the subfield) is patched in below, once
+	// the compressed size of the member is known.
+	gz.Header.Extra = []byte{bgzfSI1, bgzfSI2, 2, 0, 0, 0}
+	if _, err := gz.Write(z.buf.Bytes()); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	raw := member.Bytes()
+	bsizeOff := bytes.Index(raw, []byte{bgzfSI1, bgzfSI2, 2, 0}) + 4
+	binary.LittleEndian.PutUint16(raw[bsizeOff:bsizeOff+2], uint16(len(raw)-1))
+
+	if _, err := z.w.Write(raw); err != nil {
+		return err
+	}
+	z.written += int64(len(raw))
+	z.buf.Reset()
+	return nil
+}
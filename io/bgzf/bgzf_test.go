@@ -0,0 +1,231 @@
+// Copyright ©2011-2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bgzf
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+// TestRoundTripMultiBlock writes enough data to span several BGZF blocks
+// and checks that a full sequential read recovers it exactly.
+func TestRoundTripMultiBlock(t *testing.T) {
+	want := make([]byte, 200000) // forces more than MaxBlockSize of data, i.e. several blocks.
+	for i := range want {
+		want[i] = byte(i)
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if _, err := w.Write(want); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("round trip mismatch: got %d bytes, want %d bytes", len(got), len(want))
+	}
+}
+
+// TestRoundTripTwoBlocks exercises the boundary between exactly two blocks.
+func TestRoundTripTwoBlocks(t *testing.T) {
+	want := bytes.Repeat([]byte{'x'}, 70000)
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if _, err := w.Write(want); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("round trip mismatch: got %d bytes, want %d bytes", len(got), len(want))
+	}
+}
+
+// TestSeekAcrossBlocks checks that Seek can land in the second of several
+// blocks and that reading from there recovers the remaining data.
+func TestSeekAcrossBlocks(t *testing.T) {
+	want := make([]byte, 200000)
+	for i := range want {
+		want[i] = byte(i)
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if _, err := w.Write(want); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	src := bytes.NewReader(buf.Bytes())
+	r, err := NewReader(src)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+
+	const skip = MaxBlockSize + 100 // into the second block.
+	if _, err := io.CopyN(io.Discard, r, skip); err != nil {
+		t.Fatalf("CopyN: %v", err)
+	}
+	mark, err := r.Offset()
+	if err != nil {
+		t.Fatalf("Offset: %v", err)
+	}
+
+	// Re-open the stream and seek directly to the recorded virtual offset.
+	r2, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	if err := r2.Seek(mark); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+
+	got, err := io.ReadAll(r2)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(got, want[skip:]) {
+		t.Fatalf("seek mismatch: got %d bytes, want %d bytes", len(got), len(want)-skip)
+	}
+}
+
+// TestSeekFromFile checks that Seek works when NewReader is handed a real
+// *os.File, the way fasta.NewReaderGZ and fastq.NewReaderGZ construct it
+// from a caller-supplied source.
+func TestSeekFromFile(t *testing.T) {
+	want := make([]byte, 200000)
+	for i := range want {
+		want[i] = byte(i)
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if _, err := w.Write(want); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	dir := t.TempDir()
+	path := dir + "/test.bgzf"
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+
+	r, err := NewReader(f)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+
+	const skip = MaxBlockSize + 100 // into the second block.
+	if _, err := io.CopyN(io.Discard, r, skip); err != nil {
+		t.Fatalf("CopyN: %v", err)
+	}
+	mark, err := r.Offset()
+	if err != nil {
+		t.Fatalf("Offset: %v", err)
+	}
+
+	if err := r.Seek(mark); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(got, want[skip:]) {
+		t.Fatalf("seek mismatch: got %d bytes, want %d bytes", len(got), len(want)-skip)
+	}
+}
+
+// TestPassthroughUncompressed checks that data with no gzip magic header is
+// read back unchanged, and that Offset and Seek report ErrNotSeekable for
+// it rather than misbehaving.
+func TestPassthroughUncompressed(t *testing.T) {
+	want := []byte(">seq1\nACGT\n")
+
+	r, err := NewReader(bytes.NewReader(want))
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("passthrough mismatch: got %q, want %q", got, want)
+	}
+	if _, err := r.Offset(); err != ErrNotSeekable {
+		t.Fatalf("Offset: got %v, want ErrNotSeekable", err)
+	}
+	if err := r.Seek(0); err != ErrNotSeekable {
+		t.Fatalf("Seek: got %v, want ErrNotSeekable", err)
+	}
+}
+
+// TestSeekNotSeekableSource checks that wrapping a BGZF stream in a
+// non-seekable io.Reader still decompresses correctly, but reports
+// ErrNotSeekable for Seek rather than silently discarding data.
+func TestSeekNotSeekableSource(t *testing.T) {
+	want := bytes.Repeat([]byte{'y'}, 70000)
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if _, err := w.Write(want); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// io.MultiReader strips the io.ReadSeeker interface the underlying
+	// bytes.Reader would otherwise expose.
+	r, err := NewReader(io.MultiReader(bytes.NewReader(buf.Bytes())))
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("round trip mismatch: got %d bytes, want %d bytes", len(got), len(want))
+	}
+	if err := r.Seek(0); err != ErrNotSeekable {
+		t.Fatalf("Seek: got %v, want ErrNotSeekable", err)
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright ©2011-2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package fasta provides types to read and write FASTA format files.
+package fasta
+
+import (
+	"code.google.com/p/biogo/alphabet"
+	"code.google.com/p/biogo/io/seqio"
+	"code.google.com/p/biogo/seq"
+
+	"bufio"
+	"bytes"
+	"io"
+)
+
+var (
+	_ seqio.Reader = (*Reader)(nil)
+	_ seqio.Writer = (*Writer)(nil)
+)
+
+// Fasta sequence format reader type.
+type Reader struct {
+	r         *bufio.Reader
+	t         seqio.SequenceAppender
+	IDPrefix  []byte
+	SeqPrefix []byte
+	last      []byte
+}
+
+// Returns a new fasta format reader using r. Sequences returned by the Reader are copied
+// from the provided template.
+func NewReader(r io.Reader, template seqio.SequenceAppender) *Reader {
+	return &Reader{
+		r:         bufio.NewReader(r),
+		t:         template,
+		IDPrefix:  []byte(">"), // default delimiters
+		SeqPrefix: []byte(""),  // default delimiters
+	}
+}
+
+// Read a single sequence and return it or an error.
+func (r *Reader) Read() (seq.Sequence, error) {
+	var line, label, body []byte
+	label = r.last
+
+	for {
+		var err error
+		if line, err = r.r.ReadBytes('\n'); err != nil && err != io.EOF {
+			return nil, err
+		} else if err == io.EOF && len(line) == 0 {
+			if r.last == nil || label == nil {
+				return nil, io.EOF
+			}
+			r.last = nil
+			return r.build(label, body), nil
+		}
+		eof := err == io.EOF
+
+		line = bytes.TrimSpace(line)
+		if len(line) != 0 {
+			switch {
+			case bytes.HasPrefix(line, r.IDPrefix):
+				if r.last == nil {
+					label = line[len(r.IDPrefix):]
+					r.last = append([]byte(nil), label...)
+				} else {
+					label = r.last
+					r.last = append([]byte(nil), line[len(r.IDPrefix):]...)
+					return r.build(label, body), nil
+				}
+			case bytes.HasPrefix(line, r.SeqPrefix):
+				body = append(body, bytes.Join(bytes.Fields(line[len(r.SeqPrefix):]), nil)...)
+			}
+		}
+
+		if eof {
+			r.last = nil
+			return r.build(label, body), nil
+		}
+	}
+}
+
+// build clones the reader's template and populates it with the parsed
+// label and sequence body.
+func (r *Reader) build(label, body []byte) seq.Sequence {
+	t := r.t.Clone().(seqio.SequenceAppender)
+
+	fieldMark := bytes.IndexAny(label, " \t")
+	if fieldMark < 0 {
+		t.SetName(string(label))
+	} else {
+		t.SetName(string(label[:fieldMark]))
+		t.SetDescription(string(label[fieldMark+1:]))
+	}
+
+	letters := make([]alphabet.Letter, len(body))
+	for i, b := range body {
+		letters[i] = alphabet.Letter(b)
+	}
+	t.AppendLetters(letters...)
+
+	return t
+}
+
+// Fasta sequence format writer type.
+type Writer struct {
+	w         io.Writer
+	IDPrefix  string
+	SeqPrefix string
+	Width     int
+}
+
+// Returns a new fasta format writer using w. Sequence lines are wrapped at width
+// bases; a width <= 0 writes each sequence on a single line.
+func NewWriter(w io.Writer, width int) *Writer {
+	return &Writer{
+		w:         w,
+		IDPrefix:  ">", // default delimiters
+		SeqPrefix: "",  // default delimiters
+		Width:     width,
+	}
+}
+
+// Write a single sequence and return the number of bytes written and any error.
+func (w *Writer) Write(s seq.Sequence) (n int, err error) {
+	var _n int
+
+	n, err = w.writeHeader(s)
+	if err != nil {
+		return
+	}
+
+	width := w.Width
+	if width <= 0 {
+		width = s.Len()
+	}
+	for i := 0; i < s.Len(); i += width {
+		end := i + width
+		if end > s.Len() {
+			end = s.Len()
+		}
+		_n, err = io.WriteString(w.w, w.SeqPrefix)
+		if n += _n; err != nil {
+			return
+		}
+		for j := i; j < end; j++ {
+			_n, err = w.w.Write([]byte{byte(s.At(j).L)})
+			if n += _n; err != nil {
+				return
+			}
+		}
+		_n, err = w.w.Write([]byte{'\n'})
+		if n += _n; err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (w *Writer) writeHeader(s seq.Sequence) (n int, err error) {
+	var _n int
+	n, err = io.WriteString(w.w, w.IDPrefix)
+	if err != nil {
+		return
+	}
+	_n, err = io.WriteString(w.w, s.Name())
+	if n += _n; err != nil {
+		return
+	}
+	if desc := s.Description(); len(desc) != 0 {
+		_n, err = w.w.Write([]byte{' '})
+		if n += _n; err != nil {
+			return
+		}
+		_n, err = io.WriteString(w.w, desc)
+		if n += _n; err != nil {
+			return
+		}
+	}
+	_n, err = w.w.Write([]byte("\n"))
+	n += _n
+	return
+}
@@ -0,0 +1,82 @@
+// Copyright ©2011-2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fasta
+
+import (
+	"code.google.com/p/biogo/io/bgzf"
+	"code.google.com/p/biogo/io/seqio"
+
+	"bufio"
+	"io"
+)
+
+// GZReader wraps a Reader, decompressing a gzip or BGZF source via a
+// bgzf.Reader so that, for BGZF input, Offset and Seek can address data by
+// the same virtual offset scheme a FASTA .fai index uses.
+type GZReader struct {
+	*Reader
+	z *bgzf.Reader
+}
+
+// NewReaderGZ returns a GZReader over r. bgzf.NewReader sniffs r for a gzip
+// magic header and, within that, BGZF's "BC" extra subfield: an uncompressed
+// stream is read back unchanged, a plain gzip stream is decompressed as one
+// continuous member, and a BGZF stream is decompressed one block at a time
+// so Offset and Seek become available. Passing r through to bgzf.NewReader
+// directly, rather than pre-wrapping it here, matters when r is itself an
+// io.ReadSeeker (an *os.File, say): only bgzf.NewReader can sniff the magic
+// header and still hand Seek the original, seekable r afterwards. Sequences
+// returned by the Reader are copied from the provided template.
+func NewReaderGZ(r io.Reader, template seqio.SequenceAppender) (*GZReader, error) {
+	z, err := bgzf.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &GZReader{Reader: NewReader(z, template), z: z}, nil
+}
+
+// Offset returns the virtual offset of the next sequence Read will return.
+// It returns bgzf.ErrNotSeekable unless the underlying stream is BGZF.
+func (r *GZReader) Offset() (uint64, error) {
+	return r.z.Offset()
+}
+
+// Seek moves to the BGZF block identified by the compressed offset
+// component of virtualOffset and discards the within-block prefix
+// identified by its uncompressed offset component, so the next Read resumes
+// at virtualOffset. It returns bgzf.ErrNotSeekable unless the underlying
+// stream is BGZF over a seekable source.
+func (r *GZReader) Seek(virtualOffset uint64) error {
+	if err := r.z.Seek(virtualOffset); err != nil {
+		return err
+	}
+	r.Reader.r = bufio.NewReader(r.z)
+	r.Reader.last = nil
+	return nil
+}
+
+// GZWriter wraps a Writer, compressing its output as a BGZF stream so that
+// readers can recover the virtual offset of any sequence it writes.
+type GZWriter struct {
+	*Writer
+	z *bgzf.Writer
+}
+
+// NewWriterGZ returns a GZWriter that BGZF-compresses sequences written
+// through it to w. Sequence lines are wrapped as for NewWriter.
+func NewWriterGZ(w io.Writer, width int) *GZWriter {
+	z := bgzf.NewWriter(w)
+	return &GZWriter{Writer: NewWriter(z, width), z: z}
+}
+
+// Offset returns the virtual offset of the next byte that will be written.
+func (w *GZWriter) Offset() uint64 {
+	return w.z.Offset()
+}
+
+// Close flushes any buffered output and closes the underlying BGZF stream.
+func (w *GZWriter) Close() error {
+	return w.z.Close()
+}
@@ -0,0 +1,103 @@
+// Copyright ©2011-2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fasta
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"code.google.com/p/biogo/alphabet"
+	"code.google.com/p/biogo/seq"
+)
+
+// TestGZReaderSeekFromFile checks that a GZReader constructed the way real
+// callers build one — from an *os.File — can Seek, since NewReaderGZ must
+// pass the file through to bgzf.NewReader unwrapped for its io.ReadSeeker to
+// survive.
+func TestGZReaderSeekFromFile(t *testing.T) {
+	var records []*seq.Seq
+	for i := 0; i < 64; i++ {
+		records = append(records, seq.New("seq", []alphabet.Letter("ACGTACGTACGTACGTACGT"), alphabet.DNA))
+	}
+
+	var plain bytes.Buffer
+	w := NewWriter(&plain, 60)
+	for _, s := range records {
+		if _, err := w.Write(s); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	var compressed bytes.Buffer
+	gzw := NewWriterGZ(&compressed, 60)
+	for _, s := range records {
+		if _, err := gzw.Write(s); err != nil {
+			t.Fatalf("Write (gz): %v", err)
+		}
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	dir := t.TempDir()
+	path := dir + "/test.fasta.gz"
+	if err := os.WriteFile(path, compressed.Bytes(), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+
+	template := &seq.Seq{Annotation: seq.Annotation{Alpha: alphabet.DNA}}
+	r, err := NewReaderGZ(f, template)
+	if err != nil {
+		t.Fatalf("NewReaderGZ: %v", err)
+	}
+
+	// Read the first record, then record the offset of the second.
+	if _, err := r.Read(); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	mark, err := r.Offset()
+	if err != nil {
+		t.Fatalf("Offset: %v", err)
+	}
+
+	// Drain the rest so we know what Read should return once we seek back.
+	var want []seq.Sequence
+	for {
+		s, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		want = append(want, s)
+	}
+
+	if err := r.Seek(mark); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	for i := 0; ; i++ {
+		s, err := r.Read()
+		if err == io.EOF {
+			if i != len(want) {
+				t.Fatalf("got %d records after seek, want %d", i, len(want))
+			}
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read after Seek: %v", err)
+		}
+		if s.String() != want[i].String() {
+			t.Fatalf("record %d mismatch after seek: got %q, want %q", i, s.String(), want[i].String())
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright ©2011-2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fasta
+
+import (
+	"code.google.com/p/biogo/alphabet"
+	"code.google.com/p/biogo/seq"
+
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// IndexRecord describes a single sequence entry in a samtools-style FASTA
+// index (.fai): the sequence name, its length in bases, the byte offset of
+// its first base, the number of bases per line and the number of bytes per
+// line (bases plus line terminator).
+type IndexRecord struct {
+	Name      string
+	Length    int
+	Offset    int64
+	LineBases int
+	LineWidth int
+}
+
+// validate rejects an IndexRecord whose fields could not have come from a
+// real FASTA file, so that a hand-edited or corrupted .fai fails loudly in
+// ReadIndex rather than later as a division by zero or an out-of-bounds
+// seek in Subseq.
+func (rec IndexRecord) validate() error {
+	if rec.Length < 0 {
+		return fmt.Errorf("fasta: invalid length %d in index for %q: must not be negative", rec.Length, rec.Name)
+	}
+	if rec.Offset < 0 {
+		return fmt.Errorf("fasta: invalid offset %d in index for %q: must not be negative", rec.Offset, rec.Name)
+	}
+	if rec.Length > 0 && (rec.LineBases <= 0 || rec.LineWidth <= 0) {
+		return fmt.Errorf("fasta: invalid line geometry in index for %q: linebases and linewidth must be positive for a non-empty sequence", rec.Name)
+	}
+	return nil
+}
+
+// Index is a FASTA index (.fai) that allows a sequence, or part of a
+// sequence, to be located by seeking directly into the indexed FASTA file
+// rather than scanning it from the start.
+type Index struct {
+	byName map[string]IndexRecord
+	names  []string
+}
+
+// ReadIndex reads a samtools-style FASTA index from r. Each index line must
+// have the tab-separated fields name, length, offset, linebases and
+// linewidth.
+func ReadIndex(r io.Reader) (*Index, error) {
+	idx := &Index{byName: make(map[string]IndexRecord)}
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("fasta: malformed index line: %q", line)
+		}
+
+		rec := IndexRecord{Name: fields[0]}
+		var err error
+		if rec.Length, err = strconv.Atoi(fields[1]); err != nil {
+			return nil, fmt.Errorf("fasta: invalid length in index for %q: %v", rec.Name, err)
+		}
+		if rec.Offset, err = strconv.ParseInt(fields[2], 10, 64); err != nil {
+			return nil, fmt.Errorf("fasta: invalid offset in index for %q: %v", rec.Name, err)
+		}
+		if rec.LineBases, err = strconv.Atoi(fields[3]); err != nil {
+			return nil, fmt.Errorf("fasta: invalid linebases in index for %q: %v", rec.Name, err)
+		}
+		if rec.LineWidth, err = strconv.Atoi(fields[4]); err != nil {
+			return nil, fmt.Errorf("fasta: invalid linewidth in index for %q: %v", rec.Name, err)
+		}
+		if err := rec.validate(); err != nil {
+			return nil, err
+		}
+
+		if _, exists := idx.byName[rec.Name]; !exists {
+			idx.names = append(idx.names, rec.Name)
+		}
+		idx.byName[rec.Name] = rec
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	return idx, nil
+}
+
+// Names returns the sequence names held by the index, in index order.
+func (idx *Index) Names() []string { return idx.names }
+
+// Record returns the index record for the named sequence.
+func (idx *Index) Record(name string) (IndexRecord, bool) {
+	rec, ok := idx.byName[name]
+	return rec, ok
+}
+
+// BuildIndex scans the FASTA data in r once, writing a samtools-style .fai
+// index to w. The FASTA format requires every sequence line but the last of
+// a record to share a single width; BuildIndex returns an error identifying
+// the first record that breaks this rule.
+func BuildIndex(r io.Reader, w io.Writer) error {
+	br := bufio.NewReader(r)
+
+	type pending struct {
+		name      string
+		seqOffset int64
+		lineWidth int
+		lineBases []int
+	}
+	var rec *pending
+	var offset int64
+
+	flush := func() error {
+		if rec == nil {
+			return nil
+		}
+		length := 0
+		lineBases := 0
+		for i, n := range rec.lineBases {
+			length += n
+			switch {
+			case i == 0:
+				lineBases = n
+			case i < len(rec.lineBases)-1 && n != lineBases:
+				return fmt.Errorf("fasta: %q: non-uniform line length, a .fai index requires a constant line width", rec.name)
+			case i == len(rec.lineBases)-1 && n > lineBases:
+				return fmt.Errorf("fasta: %q: non-uniform line length, a .fai index requires a constant line width", rec.name)
+			}
+		}
+		_, err := fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", rec.name, length, rec.seqOffset, lineBases, rec.lineWidth)
+		return err
+	}
+
+	for {
+		line, err := br.ReadBytes('\n')
+		n := int64(len(line))
+		text := bytes.TrimRight(line, "\r\n")
+
+		switch {
+		case len(text) == 0:
+			// blank line between or within records; ignore.
+		case text[0] == '>':
+			if ferr := flush(); ferr != nil {
+				return ferr
+			}
+			name := string(text[1:])
+			if fieldMark := bytes.IndexAny(text, " \t"); fieldMark >= 0 {
+				name = string(text[1:fieldMark])
+			}
+			rec = &pending{name: name, seqOffset: offset + n}
+		default:
+			if rec == nil {
+				return errors.New("fasta: sequence data encountered before any header")
+			}
+			if len(rec.lineBases) == 0 {
+				rec.lineWidth = int(n)
+			}
+			rec.lineBases = append(rec.lineBases, len(text))
+		}
+
+		offset += n
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return flush()
+}
+
+// IndexedReader provides random-access reads into an indexed FASTA file,
+// seeking directly to the requested region rather than scanning from the
+// start of the file.
+type IndexedReader struct {
+	r     io.ReadSeeker
+	idx   *Index
+	alpha alphabet.Alphabet
+}
+
+// NewIndexedReader returns an IndexedReader that locates sequences in r
+// using idx. Sequences returned by Seq and Subseq are built over alpha.
+func NewIndexedReader(r io.ReadSeeker, idx *Index, alpha alphabet.Alphabet) *IndexedReader {
+	return &IndexedReader{r: r, idx: idx, alpha: alpha}
+}
+
+// Seq returns the full named sequence.
+func (r *IndexedReader) Seq(name string) (*seq.Seq, error) {
+	rec, ok := r.idx.Record(name)
+	if !ok {
+		return nil, fmt.Errorf("fasta: sequence %q not found in index", name)
+	}
+	return r.Subseq(name, 0, rec.Length)
+}
+
+// Subseq returns the region [start, end) of the named sequence, seeking
+// directly to the requested bases rather than scanning the file from its
+// start.
+func (r *IndexedReader) Subseq(name string, start, end int) (*seq.Seq, error) {
+	rec, ok := r.idx.Record(name)
+	if !ok {
+		return nil, fmt.Errorf("fasta: sequence %q not found in index", name)
+	}
+	if start < 0 || end > rec.Length || start > end {
+		return nil, fmt.Errorf("fasta: region [%d, %d) out of bounds for %q (length %d)", start, end, name, rec.Length)
+	}
+
+	body := make([]byte, 0, end-start)
+	for pos := start; pos < end; {
+		line := pos / rec.LineBases
+		col := pos % rec.LineBases
+		at := rec.Offset + int64(line)*int64(rec.LineWidth) + int64(col)
+		if _, err := r.r.Seek(at, io.SeekStart); err != nil {
+			return nil, err
+		}
+
+		want := rec.LineBases - col
+		if remaining := end - pos; remaining < want {
+			want = remaining
+		}
+		buf := make([]byte, want)
+		if _, err := io.ReadFull(r.r, buf); err != nil {
+			return nil, err
+		}
+		body = append(body, buf...)
+		pos += want
+	}
+
+	letters := make([]alphabet.Letter, len(body))
+	for i, b := range body {
+		letters[i] = alphabet.Letter(b)
+	}
+
+	return seq.New(name, letters, r.alpha), nil
+}
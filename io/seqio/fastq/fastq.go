@@ -25,6 +25,20 @@ type Encoder interface {
 	Encoding() alphabet.Encoding
 }
 
+// QIDSetter is satisfied by a sequence template that can record the literal
+// text of a FASTQ '+' line, so that Reader.Read can preserve it for Writer to
+// round-trip with QID set.
+type QIDSetter interface {
+	SetQID(string)
+}
+
+// QIDer is satisfied by a sequence that carries a '+' line recorded by
+// QIDSetter, allowing Writer to reproduce it verbatim instead of
+// reconstructing it from Name and Description.
+type QIDer interface {
+	QID() string
+}
+
 // Fastq sequence format reader type.
 type Reader struct {
 	r   *bufio.Reader
@@ -49,18 +63,83 @@ func NewReader(r io.Reader, template seqio.SequenceAppender) *Reader {
 	}
 }
 
-// Read a single sequence and return it or an error.
-// TODO: Does not read multi-line fastq.
+// Read a single sequence and return it or an error. Multi-line FASTQ is
+// supported: since '@' and '+' are themselves valid quality characters, the
+// sequence is first accumulated up to the '+' separator, then quality lines
+// are read and appended until the accumulated quality length equals the
+// accumulated sequence length, treating any leading '@' or '+' encountered
+// before that point as quality data rather than the start of a new record.
+// The '+' line must be either empty, repeat the sequence header verbatim, or
+// repeat just its ID; in the latter two cases the literal text is recorded
+// via QIDSetter, if the template implements it, so Writer can round-trip it
+// with QID set.
 func (r *Reader) Read() (seq.Sequence, error) {
-	var (
-		buff, line, label []byte
-		isPrefix          bool
-		seqBuff           []alphabet.QLetter
-		t                 seqio.SequenceAppender
-	)
+	header, err := r.nextLine()
+	if err != nil {
+		return nil, err
+	}
+	if len(header) == 0 || header[0] != '@' {
+		return nil, errors.New("fastq: expected '@' header line")
+	}
+	t := r.readHeader(header)
+
+	var residues []byte
+	for {
+		line, err := r.nextLine()
+		if err != nil {
+			if err == io.EOF {
+				return nil, errors.New("fastq: truncated record: missing '+' separator")
+			}
+			return nil, err
+		}
+		if line[0] == '+' {
+			if len(line) > 1 {
+				if !bytes.Equal(line[1:], header[1:]) && !bytes.Equal(line[1:], headerID(header)) {
+					return nil, errors.New("fastq: quality header does not match sequence header")
+				}
+				if qs, ok := t.(QIDSetter); ok {
+					qs.SetQID(string(line[1:]))
+				}
+			}
+			break
+		}
+		residues = append(residues, bytes.Join(bytes.Fields(line), nil)...)
+	}
 
-	inQual := false
+	seqBuff := make([]alphabet.QLetter, len(residues))
+	for i, b := range residues {
+		seqBuff[i].L = alphabet.Letter(b)
+	}
 
+	for got := 0; got < len(seqBuff); {
+		line, err := r.nextLine()
+		if err != nil {
+			if err == io.EOF {
+				return nil, errors.New("fastq: sequence/quality length mismatch: unexpected EOF")
+			}
+			return nil, err
+		}
+		line = bytes.Join(bytes.Fields(line), nil)
+		if got+len(line) > len(seqBuff) {
+			return nil, errors.New("fastq: sequence/quality length mismatch")
+		}
+		for _, b := range line {
+			seqBuff[got].Q = r.enc.DecodeToQphred(b)
+			got++
+		}
+	}
+	t.AppendQLetters(seqBuff...)
+
+	return t, nil
+}
+
+// nextLine returns the next non-blank, whitespace-trimmed line from the
+// underlying reader, reassembling lines split by bufio.Reader.ReadLine.
+func (r *Reader) nextLine() ([]byte, error) {
+	var (
+		buff, line []byte
+		isPrefix   bool
+	)
 	for {
 		var err error
 		if buff, isPrefix, err = r.r.ReadLine(); err != nil {
@@ -70,45 +149,21 @@ func (r *Reader) Read() (seq.Sequence, error) {
 		if isPrefix {
 			continue
 		}
-
 		line = bytes.TrimSpace(line)
-		if len(line) == 0 {
-			continue
-		}
-		switch {
-		case !inQual && line[0] == '@':
-			t = r.readHeader(line)
-			label = line
-		case !inQual && line[0] == '+':
-			if len(label) == 0 {
-				return nil, errors.New("fastq: no header line parsed before +line in fastq format")
-			}
-			if len(line) > 1 && bytes.Compare(label[1:], line[1:]) != 0 {
-				return nil, errors.New("fastq: quality header does not match sequence header")
-			}
-			inQual = true
-		case !inQual:
-			line = bytes.Join(bytes.Fields(line), nil)
-			seqBuff = make([]alphabet.QLetter, len(line))
-			for i := range line {
-				seqBuff[i].L = alphabet.Letter(line[i])
-			}
-		case inQual:
-			line = bytes.Join(bytes.Fields(line), nil)
-			if len(line) != len(seqBuff) {
-				return nil, errors.New("fastq: sequence/quality length mismatch")
-			}
-			for i := range line {
-				seqBuff[i].Q = r.enc.DecodeToQphred(line[i])
-			}
-			t.AppendQLetters(seqBuff...)
-
-			return t, nil
+		if len(line) != 0 {
+			return line, nil
 		}
 		line = nil
 	}
+}
 
-	panic("cannot reach")
+// headerID returns the ID portion of a '@' or '+' header line, i.e. the text
+// up to the first field separator, with the leading '@' or '+' stripped.
+func headerID(line []byte) []byte {
+	if fieldMark := bytes.IndexAny(line, " \t"); fieldMark >= 0 {
+		return line[1:fieldMark]
+	}
+	return line[1:]
 }
 
 func (r *Reader) readHeader(line []byte) seqio.SequenceAppender {
@@ -127,7 +182,7 @@ func (r *Reader) readHeader(line []byte) seqio.SequenceAppender {
 // Fastq sequence format writer type.
 type Writer struct {
 	w   io.Writer
-	QID bool // Include ID on +lines
+	QID bool // Include ID on +lines; reproduces the original '+' line verbatim via QIDer when the sequence has one, otherwise rebuilds it from Name and Description.
 }
 
 // Returns a new fastq format writer using w.
@@ -194,6 +249,20 @@ func (w *Writer) writeHeader(prefix byte, s seq.Sequence) (n int, err error) {
 	if err != nil {
 		return
 	}
+	if prefix == '+' {
+		if q, ok := s.(QIDer); ok {
+			if qid := q.QID(); len(qid) != 0 {
+				_n, err = io.WriteString(w.w, qid)
+				n += _n
+				if err != nil {
+					return
+				}
+				_n, err = w.w.Write([]byte("\n"))
+				n += _n
+				return
+			}
+		}
+	}
 	_n, err = io.WriteString(w.w, s.Name())
 	if n += _n; err != nil {
 		return
@@ -0,0 +1,96 @@
+// Copyright ©2011-2013 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fastq
+
+import (
+	"code.google.com/p/biogo/io/bgzf"
+	"code.google.com/p/biogo/io/seqio"
+
+	"bufio"
+	"errors"
+	"io"
+)
+
+// GZReader pairs a Reader with the bgzf.Reader decompressing its input, so
+// that reads from a paired-end or streaming FASTQ source can still be
+// addressed by BGZF virtual offset when the underlying file is block
+// compressed.
+type GZReader struct {
+	*Reader
+	z *bgzf.Reader
+}
+
+// NewReaderGZ returns a GZReader over r, delegating directly to
+// bgzf.NewReader to detect compression: a stream with no gzip magic header
+// is read back unchanged, a plain gzip stream decompresses as one
+// continuous member, and a BGZF stream (gzip plus a "BC" extra subfield)
+// decompresses one block at a time, making Offset and Seek available. r is
+// handed to bgzf.NewReader as-is, without an intervening buffered wrapper
+// here, so that when r is an io.ReadSeeker (typically an *os.File) that
+// seekability survives into the returned GZReader rather than being hidden
+// behind a non-seekable buffer. Sequences returned by the Reader are copied
+// from the provided template.
+func NewReaderGZ(r io.Reader, template seqio.SequenceAppender) (*GZReader, error) {
+	z, err := bgzf.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &GZReader{Reader: NewReader(z, template), z: z}, nil
+}
+
+// Offset returns the virtual offset of the next sequence Read will return.
+// It returns bgzf.ErrNotSeekable unless the underlying stream is BGZF, and an
+// error when the data buffered by Read but not yet used began in an earlier
+// block than the one the bgzf.Reader has moved on to.
+func (r *GZReader) Offset() (uint64, error) {
+	off, err := r.z.Offset()
+	if err != nil {
+		return 0, err
+	}
+	// Step back over data taken from r.z but still held in r.Reader's buffer.
+	block, within := bgzf.SplitOffset(off)
+	buffered := r.Reader.r.Buffered()
+	if buffered > int(within) {
+		return 0, errors.New("fastq: buffered data spans a BGZF block boundary")
+	}
+	return bgzf.Offset(block, within-uint16(buffered)), nil
+}
+
+// Seek moves to the BGZF block identified by the compressed offset
+// component of virtualOffset and discards the within-block prefix
+// identified by its uncompressed offset component, so the next Read resumes
+// at virtualOffset. It returns bgzf.ErrNotSeekable unless the underlying
+// stream is BGZF over a seekable source.
+func (r *GZReader) Seek(virtualOffset uint64) error {
+	if err := r.z.Seek(virtualOffset); err != nil {
+		return err
+	}
+	r.Reader.r = bufio.NewReader(r.z)
+	return nil
+}
+
+// GZWriter wraps a Writer, compressing its output as a BGZF stream so that
+// readers can recover the virtual offset of any sequence it writes.
+type GZWriter struct {
+	*Writer
+	z *bgzf.Writer
+}
+
+// NewWriterGZ returns a GZWriter that BGZF-compresses sequences written
+// through it to w.
+func NewWriterGZ(w io.Writer) *GZWriter {
+	z := bgzf.NewWriter(w)
+	return &GZWriter{Writer: NewWriter(z), z: z}
+}
+
+// Offset returns the virtual offset of the next byte that will be written.
+func (w *GZWriter) Offset() uint64 {
+	return w.z.Offset()
+}
+
+// Close flushes any buffered output and closes the underlying BGZF stream.
+func (w *GZWriter) Close() error {
+	return w.z.Close()
+}